@@ -0,0 +1,279 @@
+// Copyright 2012 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+// Package ber provides a public, allocation-free BER/X.690 codec for the
+// SNMP primitives gosnmp's internal helpers (marshalObjectIdentifier,
+// parseObjectIdentifier, marshalUint32, marshalUint64, ...) encode and
+// decode one []byte at a time. Encoder writes into a caller-supplied
+// *bytes.Buffer and Decoder reads without copying, which matters in
+// bulk-walk workloads where a GetBulk response carries hundreds of
+// VarBinds: see BenchmarkDecodeOIDZeroAlloc.
+package ber
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sync"
+)
+
+// Decode/encode error modes. These are distinct from the gosnmp package's
+// own error variables so that ber has no dependency on it.
+var (
+	ErrInvalidOID      = errors.New("ber: invalid object identifier")
+	ErrTruncatedOID    = errors.New("ber: truncated object identifier")
+	ErrOIDArcOverflow  = errors.New("ber: object identifier arc overflows uint32")
+	ErrIntegerOverflow = errors.New("ber: integer overflows uint64")
+	ErrInvalidLength   = errors.New("ber: invalid length")
+	ErrTruncatedLength = errors.New("ber: truncated length")
+)
+
+// base128Scratch pools the small buffers EncodeOID's per-arc encoding needs
+// to compute a base-128 run in reverse order before writing it forward.
+var base128Scratch = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 8)
+		return &b
+	},
+}
+
+// uint64Scratch pools the 8-byte buffers EncodeCounter64 uses to compute
+// the BigEndian encoding before trimming leading zeros.
+var uint64Scratch = sync.Pool{
+	New: func() interface{} {
+		var b [8]byte
+		return &b
+	},
+}
+
+// lengthScratch pools the small buffers EncodeLength's long-form encoding
+// needs to compute a length's big-endian octets in reverse order before
+// writing them forward.
+var lengthScratch = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 4)
+		return &b
+	},
+}
+
+// Encoder writes BER-encoded SNMP values into a caller-supplied buffer.
+// Unlike the gosnmp package's unexported marshal* helpers, a method call
+// never allocates a fresh []byte of its own.
+type Encoder struct {
+	buf *bytes.Buffer
+}
+
+// NewEncoder returns an Encoder that appends BER-encoded values to buf.
+func NewEncoder(buf *bytes.Buffer) *Encoder {
+	return &Encoder{buf: buf}
+}
+
+// EncodeInteger32 appends the minimal two's-complement encoding of v, per
+// X.690 8.3.2.
+func (e *Encoder) EncodeInteger32(v int32) {
+	val := uint32(v)
+	switch {
+	case val&0xFFFFFF80 == 0 || val&0xFFFFFF80 == 0xFFFFFF80:
+		e.buf.WriteByte(byte(val))
+	case val&0xFFFF8000 == 0 || val&0xFFFF8000 == 0xFFFF8000:
+		e.buf.WriteByte(byte(val >> 8))
+		e.buf.WriteByte(byte(val))
+	case val&0xFF800000 == 0 || val&0xFF800000 == 0xFF800000:
+		e.buf.WriteByte(byte(val >> 16))
+		e.buf.WriteByte(byte(val >> 8))
+		e.buf.WriteByte(byte(val))
+	default:
+		e.buf.WriteByte(byte(val >> 24))
+		e.buf.WriteByte(byte(val >> 16))
+		e.buf.WriteByte(byte(val >> 8))
+		e.buf.WriteByte(byte(val))
+	}
+}
+
+// EncodeCounter64 appends the minimal unsigned encoding of v, prepending a
+// zero byte if the top bit of the trimmed encoding would otherwise flip it
+// negative.
+func (e *Encoder) EncodeCounter64(v uint64) {
+	scratchPtr := uint64Scratch.Get().(*[8]byte)
+	defer uint64Scratch.Put(scratchPtr)
+
+	binary.BigEndian.PutUint64(scratchPtr[:], v)
+	trimmed := scratchPtr[:]
+	for len(trimmed) > 1 && trimmed[0] == 0 {
+		trimmed = trimmed[1:]
+	}
+	if trimmed[0]&0x80 != 0 {
+		e.buf.WriteByte(0x00)
+	}
+	e.buf.Write(trimmed)
+}
+
+// EncodeOID appends the base-128 encoding of oid, expressed as its
+// dotted-notation sub-identifiers (e.g. []uint32{1, 3, 6, 1, 2, 1}).
+func (e *Encoder) EncodeOID(oid []uint32) error {
+	if len(oid) < 2 || oid[0] > 6 || oid[1] >= 40 {
+		return ErrInvalidOID
+	}
+	e.buf.WriteByte(byte(oid[0]*40 + oid[1]))
+	for _, arc := range oid[2:] {
+		e.encodeBase128(arc)
+	}
+	return nil
+}
+
+// EncodeLength appends the BER length prefix for content of the given
+// length, per X.690 8.1.3: lengths under 128 are a single byte; longer
+// lengths use the long form, a leading 0x80|numOctets byte followed by the
+// minimal big-endian encoding of length.
+func (e *Encoder) EncodeLength(length int) error {
+	if length < 0 {
+		return ErrInvalidLength
+	}
+	if length < 128 {
+		e.buf.WriteByte(byte(length))
+		return nil
+	}
+
+	scratchPtr := lengthScratch.Get().(*[]byte)
+	scratch := (*scratchPtr)[:0]
+	for v := length; v > 0; v >>= 8 {
+		scratch = append(scratch, byte(v))
+	}
+	e.buf.WriteByte(0x80 | byte(len(scratch)))
+	for i := len(scratch) - 1; i >= 0; i-- {
+		e.buf.WriteByte(scratch[i])
+	}
+	*scratchPtr = scratch[:0]
+	lengthScratch.Put(scratchPtr)
+	return nil
+}
+
+func (e *Encoder) encodeBase128(n uint32) {
+	if n == 0 {
+		e.buf.WriteByte(0)
+		return
+	}
+	scratchPtr := base128Scratch.Get().(*[]byte)
+	scratch := (*scratchPtr)[:0]
+	for v := n; v > 0; v >>= 7 {
+		scratch = append(scratch, byte(v&0x7f))
+	}
+	for i := len(scratch) - 1; i >= 0; i-- {
+		b := scratch[i]
+		if i != 0 {
+			b |= 0x80
+		}
+		e.buf.WriteByte(b)
+	}
+	*scratchPtr = scratch[:0]
+	base128Scratch.Put(scratchPtr)
+}
+
+// Decoder reads BER-encoded SNMP values from a byte slice without copying
+// it.
+type Decoder struct {
+	data []byte
+}
+
+// NewDecoder returns a Decoder reading the BER content octets in data.
+// data is retained, not copied: callers must not mutate it while the
+// Decoder is in use.
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{data: data}
+}
+
+// DecodeOID decodes the object identifier content octets (i.e. with the
+// ObjectIdentifier tag and length already stripped) into dst, appending one
+// uint32 per sub-identifier and returning the extended slice. Passing a dst
+// with enough spare capacity makes this allocation-free.
+//
+// Each arc is bounded to uint32 range (it is stored in dst, after all): a
+// continuation run whose accumulated value exceeds math.MaxUint32 returns
+// ErrOIDArcOverflow, even if it would fit in the 5-continuation-byte cap the
+// pre-ber parseBase128Int used. Callers that need arcs beyond uint32 should
+// use gosnmp's parseObjectIdentifierBig instead.
+func (d *Decoder) DecodeOID(dst []uint32) ([]uint32, error) {
+	if len(d.data) == 0 {
+		return nil, ErrTruncatedOID
+	}
+
+	first := d.data[0]
+	dst = append(dst, uint32(first)/40, uint32(first)%40)
+
+	pos := 1
+	for pos < len(d.data) {
+		var v uint64
+		shifted := 0
+		for {
+			if pos >= len(d.data) {
+				return nil, ErrTruncatedOID
+			}
+			if shifted > 4 {
+				return nil, ErrOIDArcOverflow
+			}
+			b := d.data[pos]
+			v <<= 7
+			v |= uint64(b & 0x7f)
+			pos++
+			shifted++
+			if b&0x80 == 0 {
+				break
+			}
+		}
+		if v > 0xFFFFFFFF {
+			return nil, ErrOIDArcOverflow
+		}
+		dst = append(dst, uint32(v))
+	}
+	return dst, nil
+}
+
+// DecodeLength parses the BER length prefix at the start of the Decoder's
+// data, per X.690 8.1.3, and returns the total element length (header plus
+// content) and the header length (i.e. where the content octets start).
+// A buffer of 2 bytes or fewer is not parsed: both results are len(data).
+//
+// http://luca.ntop.org/Teaching/Appunti/asn1.html
+func (d *Decoder) DecodeLength() (length int, headerLen int, err error) {
+	data := d.data
+	switch {
+	case len(data) <= 2:
+		return len(data), len(data), nil
+	case int(data[1]) <= 127:
+		return int(data[1]) + 2, 2, nil
+	default:
+		numOctets := int(data[1]) & 127
+		total := 0
+		for i := 0; i < numOctets; i++ {
+			if len(data) < 2+i+1 {
+				return 0, 0, ErrTruncatedLength
+			}
+			total <<= 8
+			total += int(data[2+i])
+			if total < 0 {
+				return 0, 0, ErrTruncatedLength
+			}
+		}
+		return total + 2 + numOctets, 2 + numOctets, nil
+	}
+}
+
+// DecodeUint64 interprets the Decoder's remaining bytes as a big-endian
+// unsigned integer. This is the shape used for the INTEGER-valued fields in
+// an SNMP message header (version, request-id, error-status, error-index)
+// and for Counter64 values: a 9-byte encoding is accepted so the leading
+// zero byte X.690 prepends when the top bit of an 8-byte value would
+// otherwise look negative doesn't cause a spurious overflow.
+func (d *Decoder) DecodeUint64() (uint64, error) {
+	data := d.data
+	if len(data) > 9 || (len(data) > 8 && data[0] != 0x00) {
+		return 0, ErrIntegerOverflow
+	}
+	var ret uint64
+	for _, b := range data {
+		ret <<= 8
+		ret |= uint64(b)
+	}
+	return ret, nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2012 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"testing"
+)
+
+// ifTable-shaped OIDs (.1.3.6.1.2.1.2.2.1.10.<ifIndex>), the kind of thing a
+// GetBulk walk of a few hundred interfaces returns.
+func benchOIDs(n int) [][]uint32 {
+	oids := make([][]uint32, n)
+	for i := range oids {
+		oids[i] = []uint32{1, 3, 6, 1, 2, 1, 2, 2, 1, 10, uint32(i + 1)}
+	}
+	return oids
+}
+
+func BenchmarkEncodeOIDGetBulkResponse(b *testing.B) {
+	oids := benchOIDs(300)
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		for _, oid := range oids {
+			if err := enc.EncodeOID(oid); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkEncodeLengthZeroAlloc exercises both the short and long forms
+// EncodeLength can take for a GetBulk-sized response's VarBind-list length
+// prefix. It should report 0 allocs/op thanks to lengthScratch.
+func BenchmarkEncodeLengthZeroAlloc(b *testing.B) {
+	buf := new(bytes.Buffer)
+	enc := NewEncoder(buf)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		if err := enc.EncodeLength(127); err != nil {
+			b.Fatal(err)
+		}
+		if err := enc.EncodeLength(4096); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecodeOIDZeroAlloc decodes a GetBulk-sized response (300
+// VarBinds) worth of pre-encoded OIDs, reusing a single scratch slice
+// across iterations. It should report 0 allocs/op.
+func BenchmarkDecodeOIDZeroAlloc(b *testing.B) {
+	oids := benchOIDs(300)
+	encoded := make([][]byte, len(oids))
+	for i, oid := range oids {
+		buf := new(bytes.Buffer)
+		if err := NewEncoder(buf).EncodeOID(oid); err != nil {
+			b.Fatal(err)
+		}
+		encoded[i] = buf.Bytes()
+	}
+
+	scratch := make([]uint32, 0, 16)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, data := range encoded {
+			var err error
+			scratch, err = NewDecoder(data).DecodeOID(scratch[:0])
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright 2012 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package ber
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeDecodeOIDRoundTrip(t *testing.T) {
+	tests := [][]uint32{
+		{1, 3, 6, 1, 2, 1, 1, 1, 0},
+		{1, 3, 6, 3, 30, 11, 1, 10},
+		{1, 3, 6, 1, 2, 1, 31, 1, 1, 1, 10, 4294967295},
+	}
+
+	for _, oid := range tests {
+		buf := new(bytes.Buffer)
+		if err := NewEncoder(buf).EncodeOID(oid); err != nil {
+			t.Fatalf("EncodeOID(%v) returned unexpected error: %v", oid, err)
+		}
+		got, err := NewDecoder(buf.Bytes()).DecodeOID(nil)
+		if err != nil {
+			t.Fatalf("DecodeOID(%x) returned unexpected error: %v", buf.Bytes(), err)
+		}
+		if !reflect.DeepEqual(got, oid) {
+			t.Errorf("DecodeOID(Encode(%v)) = %v, want %v", oid, got, oid)
+		}
+	}
+}
+
+func TestDecodeOIDReusesCapacity(t *testing.T) {
+	buf := new(bytes.Buffer)
+	oid := []uint32{1, 3, 6, 1, 2, 1, 1, 1, 0}
+	if err := NewEncoder(buf).EncodeOID(oid); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := make([]uint32, 0, len(oid))
+	got, err := NewDecoder(buf.Bytes()).DecodeOID(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cap(got) != cap(dst) {
+		t.Errorf("DecodeOID reallocated: got cap %d, want %d (dst's capacity)", cap(got), cap(dst))
+	}
+}
+
+func TestDecodeOIDTruncated(t *testing.T) {
+	if _, err := NewDecoder(nil).DecodeOID(nil); err != ErrTruncatedOID {
+		t.Errorf("DecodeOID(nil) error = %v, want %v", err, ErrTruncatedOID)
+	}
+	if _, err := NewDecoder([]byte{0x2b, 0x80}).DecodeOID(nil); err != ErrTruncatedOID {
+		t.Errorf("DecodeOID of a dangling continuation byte error = %v, want %v", err, ErrTruncatedOID)
+	}
+}
+
+func TestEncodeDecodeLengthRoundTrip(t *testing.T) {
+	// DecodeLength mirrors readTLV's view of the world: byte 0 is a tag,
+	// byte 1 (and beyond, for the long form) is the length this encodes.
+	// The list starts at 2: a length of 0 gives a 2-byte buffer, which
+	// DecodeLength reports as fully consumed through its short-buffer case
+	// (see TestDecodeLengthShortBuffer) rather than by parsing the length
+	// byte, so only lengths that reach the general case are listed.
+	for _, length := range []int{2, 3, 127, 128, 255, 256, 65535, 65536} {
+		buf := new(bytes.Buffer)
+		buf.WriteByte(0x30) // a stand-in tag byte
+		if err := NewEncoder(buf).EncodeLength(length); err != nil {
+			t.Fatalf("EncodeLength(%d) returned unexpected error: %v", length, err)
+		}
+		wantHeaderLen := buf.Len()
+		encoded := append(buf.Bytes(), make([]byte, length)...)
+
+		total, headerLen, err := NewDecoder(encoded).DecodeLength()
+		if err != nil {
+			t.Fatalf("DecodeLength(%x) returned unexpected error: %v", encoded, err)
+		}
+		if headerLen != wantHeaderLen {
+			t.Errorf("DecodeLength(%x) headerLen = %d, want %d", encoded, headerLen, wantHeaderLen)
+		}
+		if total != len(encoded) {
+			t.Errorf("DecodeLength(%x) total = %d, want %d", encoded, total, len(encoded))
+		}
+	}
+}
+
+func TestEncodeLengthShortForm(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).EncodeLength(127); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x7f}; !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("EncodeLength(127) = %x, want %x", buf.Bytes(), want)
+	}
+}
+
+func TestEncodeLengthLongForm(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).EncodeLength(256); err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x82, 0x01, 0x00}; !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("EncodeLength(256) = %x, want %x", buf.Bytes(), want)
+	}
+}
+
+func TestEncodeLengthInvalid(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).EncodeLength(-1); err != ErrInvalidLength {
+		t.Errorf("EncodeLength(-1) error = %v, want %v", err, ErrInvalidLength)
+	}
+}
+
+// TestDecodeLengthShortBuffer documents a quirk inherited from the
+// pre-ber parseLength: a buffer of 2 bytes or fewer is reported as fully
+// consumed (length == headerLen == len(data)) rather than parsed as a
+// genuine length prefix. readTLV therefore accepts such a buffer as one
+// element with empty content, even when its length byte claims more.
+func TestDecodeLengthShortBuffer(t *testing.T) {
+	for _, data := range [][]byte{{}, {0x00}, {0x01, 0xAA}} {
+		length, headerLen, err := NewDecoder(data).DecodeLength()
+		if err != nil {
+			t.Fatalf("DecodeLength(%x) returned unexpected error: %v", data, err)
+		}
+		if length != len(data) || headerLen != len(data) {
+			t.Errorf("DecodeLength(%x) = (%d, %d), want (%d, %d)", data, length, headerLen, len(data), len(data))
+		}
+	}
+}
+
+func TestDecodeLengthTruncated(t *testing.T) {
+	if _, _, err := NewDecoder([]byte{0x30, 0x82, 0x01}).DecodeLength(); err != ErrTruncatedLength {
+		t.Errorf("DecodeLength of a truncated long-form length error = %v, want %v", err, ErrTruncatedLength)
+	}
+}
+
+func TestEncodeInteger32(t *testing.T) {
+	tests := []struct {
+		value    int32
+		expected []byte
+	}{
+		{0, []byte{0x00}},
+		{2, []byte{0x02}},
+		{128, []byte{0x00, 0x80}},
+		{2147483647, []byte{0x7f, 0xff, 0xff, 0xff}},
+		{-2147483648, []byte{0x80, 0x00, 0x00, 0x00}},
+		{-1, []byte{0xff}},
+	}
+	for _, test := range tests {
+		buf := new(bytes.Buffer)
+		NewEncoder(buf).EncodeInteger32(test.value)
+		if !bytes.Equal(buf.Bytes(), test.expected) {
+			t.Errorf("EncodeInteger32(%d) = %x, want %x", test.value, buf.Bytes(), test.expected)
+		}
+	}
+}
+
+func TestEncodeCounter64(t *testing.T) {
+	tests := []struct {
+		value    uint64
+		expected []byte
+	}{
+		{0, []byte{0x00}},
+		{127, []byte{0x7f}},
+		{128, []byte{0x00, 0x80}},
+		{0xFFFFFFFFFFFFFFFF, []byte{0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+	}
+	for _, test := range tests {
+		buf := new(bytes.Buffer)
+		NewEncoder(buf).EncodeCounter64(test.value)
+		if !bytes.Equal(buf.Bytes(), test.expected) {
+			t.Errorf("EncodeCounter64(%d) = %x, want %x", test.value, buf.Bytes(), test.expected)
+		}
+	}
+}
+
+func TestDecodeUint64(t *testing.T) {
+	tests := []struct {
+		data []byte
+		want uint64
+	}{
+		{[]byte{}, 0},
+		{[]byte{0x00}, 0},
+		{[]byte{0x01, 0x01}, 257},
+		{[]byte{0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, 0xFFFFFFFFFFFFFFFF},
+	}
+	for _, test := range tests {
+		got, err := NewDecoder(test.data).DecodeUint64()
+		if err != nil {
+			t.Errorf("DecodeUint64(%x) returned unexpected error: %v", test.data, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("DecodeUint64(%x) = %d, want %d", test.data, got, test.want)
+		}
+	}
+}
+
+func TestDecodeUint64Overflow(t *testing.T) {
+	data := make([]byte, 10)
+	if _, err := NewDecoder(data).DecodeUint64(); err != ErrIntegerOverflow {
+		t.Errorf("DecodeUint64(10 zero bytes) error = %v, want %v", err, ErrIntegerOverflow)
+	}
+	data = []byte{0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	if _, err := NewDecoder(data).DecodeUint64(); err != ErrIntegerOverflow {
+		t.Errorf("DecodeUint64(%x) error = %v, want %v", data, err, ErrIntegerOverflow)
+	}
+}
+
+// TestDecodeOIDArcOverflow checks the uint32 boundary explicitly: an arc
+// encoding exactly math.MaxUint32 must decode, while one a single unit
+// larger must report ErrOIDArcOverflow. Both encode to the same 5
+// continuation bytes, so the pre-ber parseBase128Int (which only counted
+// bytes, not magnitude) would have accepted both; DecodeOID deliberately
+// does not, since the result is stored in a []uint32.
+func TestDecodeOIDArcOverflow(t *testing.T) {
+	maxArc := []byte{0x2b, 0x8f, 0xff, 0xff, 0xff, 0x7f}
+	got, err := NewDecoder(maxArc).DecodeOID(nil)
+	if err != nil {
+		t.Fatalf("DecodeOID(%x) returned unexpected error: %v", maxArc, err)
+	}
+	if want := []uint32{1, 3, 4294967295}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeOID(%x) = %v, want %v", maxArc, got, want)
+	}
+
+	overflowArc := []byte{0x2b, 0x90, 0x80, 0x80, 0x80, 0x00}
+	if _, err := NewDecoder(overflowArc).DecodeOID(nil); err != ErrOIDArcOverflow {
+		t.Errorf("DecodeOID(%x) error = %v, want %v", overflowArc, err, ErrOIDArcOverflow)
+	}
+}
+
+func TestEncodeOIDInvalid(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).EncodeOID([]uint32{7, 1}); err != ErrInvalidOID {
+		t.Errorf("EncodeOID with arc0 7 error = %v, want %v", err, ErrInvalidOID)
+	}
+	if err := NewEncoder(buf).EncodeOID([]uint32{1, 40}); err != ErrInvalidOID {
+		t.Errorf("EncodeOID with arc1 40 error = %v, want %v", err, ErrInvalidOID)
+	}
+}
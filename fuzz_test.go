@@ -0,0 +1,117 @@
+// Copyright 2012 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+//go:build all || helper
+// +build all helper
+
+package gosnmp
+
+import (
+	"encoding/base64"
+	"math"
+	"testing"
+)
+
+// FuzzSnmpDecodePacket seeds from the hand-curated corpus in
+// testsInvalidSNMPResponses (see helper_test.go) and asserts that the
+// decoder never panics on arbitrary input and never returns a nil packet
+// with a nil error. The fields of a successfully decoded packet are not
+// inspected.
+func FuzzSnmpDecodePacket(f *testing.F) {
+	for _, s := range testsInvalidSNMPResponses {
+		if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+			f.Add(b)
+		}
+	}
+
+	g := &GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      161,
+		Community: "public",
+		Version:   Version2c,
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		result, err := g.SnmpDecodePacket(data)
+		if err != nil {
+			return
+		}
+		if result == nil {
+			t.Fatalf("SnmpDecodePacket(%x) returned a nil packet with a nil error", data)
+		}
+	})
+}
+
+// FuzzParseObjectIdentifier asserts parseObjectIdentifier never panics on
+// arbitrary OID bytes, seeded from the arcs exercised by
+// TestParseObjectIdentifier and TestParseObjectIdentifierWithOtherOid.
+func FuzzParseObjectIdentifier(f *testing.F) {
+	f.Add([]byte{43, 6, 1, 2, 1, 31, 1, 1, 1, 10, 143, 255, 255, 255, 127})
+	f.Add([]byte{43, 6, 3, 30, 11, 1, 10})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		parseObjectIdentifier(data)
+	})
+}
+
+// FuzzMarshalParseUint32 asserts that marshalUint32 followed by
+// parseUint64 is the identity function across the uint32 range, seeded
+// from testsMarshalUint32.
+func FuzzMarshalParseUint32(f *testing.F) {
+	for _, test := range testsMarshalUint32 {
+		f.Add(test.value)
+	}
+
+	f.Fuzz(func(t *testing.T, value uint32) {
+		marshaled, err := marshalUint32(value)
+		if err != nil {
+			t.Fatalf("marshalUint32(%d) returned unexpected error: %v", value, err)
+		}
+		parsed, err := parseUint64(marshaled)
+		if err != nil {
+			t.Fatalf("parseUint64(%x) returned unexpected error: %v", marshaled, err)
+		}
+		if parsed != uint64(value) {
+			t.Fatalf("marshalUint32(%d) -> parseUint64 round trip gave %d", value, parsed)
+		}
+	})
+}
+
+// FuzzMarshalUint64 asserts marshalUint64 never panics and that feeding its
+// output back through parseUint64 reproduces the original value.
+func FuzzMarshalUint64(f *testing.F) {
+	f.Add(uint64(0))
+	f.Add(uint64(0xFFFFFFFFFFFFFFFF))
+
+	f.Fuzz(func(t *testing.T, value uint64) {
+		marshaled, err := marshalUint64(value)
+		if err != nil {
+			t.Fatalf("marshalUint64(%d) returned unexpected error: %v", value, err)
+		}
+		parsed, err := parseUint64(marshaled)
+		if err != nil {
+			t.Fatalf("parseUint64(%x) returned unexpected error: %v", marshaled, err)
+		}
+		if parsed != value {
+			t.Fatalf("marshalUint64(%d) -> parseUint64 round trip gave %d", value, parsed)
+		}
+	})
+}
+
+// FuzzMarshalInt32 asserts marshalInt32 never panics for any value in the
+// int32 range, seeded from testsMarshalInt32.
+func FuzzMarshalInt32(f *testing.F) {
+	for _, test := range testsMarshalInt32 {
+		f.Add(test.value)
+	}
+
+	f.Fuzz(func(t *testing.T, value int) {
+		if value < math.MinInt32 || value > math.MaxInt32 {
+			t.Skip("outside int32 range")
+		}
+		if _, err := marshalInt32(value); err != nil {
+			t.Fatalf("marshalInt32(%d) returned unexpected error: %v", value, err)
+		}
+	})
+}
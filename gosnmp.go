@@ -0,0 +1,300 @@
+// Copyright 2012 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+package gosnmp
+
+import "fmt"
+
+// SnmpVersion 1, 2c and 3 implemented
+type SnmpVersion uint8
+
+// SnmpVersion 1, 2c and 3 implemented
+const (
+	Version1  SnmpVersion = 0x0
+	Version2c SnmpVersion = 0x1
+	Version3  SnmpVersion = 0x3
+)
+
+func (s SnmpVersion) String() string {
+	switch s {
+	case Version1:
+		return "1"
+	case Version2c:
+		return "2c"
+	case Version3:
+		return "3"
+	default:
+		return ""
+	}
+}
+
+// Asn1BER is the type of an encoded SNMP value, per X.690.
+type Asn1BER byte
+
+// Asn1BER values used while walking a packet's top-level structure.
+const (
+	Integer          Asn1BER = 0x02
+	OctetString      Asn1BER = 0x04
+	ObjectIdentifier Asn1BER = 0x06
+	Sequence         Asn1BER = 0x30
+)
+
+// PDUType is the type of an SNMP PDU, stored as the context-specific BER
+// tag that identifies it on the wire.
+type PDUType Asn1BER
+
+// PDUType values, per RFC 3416 section 3.
+const (
+	GetRequest     PDUType = 0xa0
+	GetNextRequest PDUType = 0xa1
+	GetResponse    PDUType = 0xa2
+	SetRequest     PDUType = 0xa3
+	Trap           PDUType = 0xa4
+	GetBulkRequest PDUType = 0xa5
+	InformRequest  PDUType = 0xa6
+	SNMPv2Trap     PDUType = 0xa7
+	Report         PDUType = 0xa8
+)
+
+// GoSNMP represents GoSNMP library state.
+type GoSNMP struct {
+	// Target is an ipv4 address.
+	Target string
+
+	// Port is a port.
+	Port uint16
+
+	// Transport is the transport protocol to use ("udp" or "tcp"); if unset "udp" will be used.
+	Transport string
+
+	// Community is an SNMP Community string.
+	Community string
+
+	// Version is an SNMP Version.
+	Version SnmpVersion
+
+	// LargeOIDArcs opts in to capturing each decoded VarBind's raw name
+	// bytes so that SnmpPDU.OIDBig can later re-decode it without the
+	// uint32 bound parseObjectIdentifier applies. The string-form
+	// SnmpPDU.Name remains the default; the one difference is that a name
+	// with an arc overflowing uint32 leaves Name empty instead of failing
+	// the decode.
+	LargeOIDArcs bool
+}
+
+// SnmpPacket represents the decoded form of an entire SNMP message.
+type SnmpPacket struct {
+	Version    SnmpVersion
+	Community  string
+	PDUType    PDUType
+	RequestID  uint32
+	Error      uint32
+	ErrorIndex uint32
+	Variables  []SnmpPDU
+}
+
+// SnmpPDU will be used when doing Get's, Set's, or when decoding a response.
+type SnmpPDU struct {
+	// Name is an oid in string format eg "1.3.6.1.4.9.27"
+	Name string
+
+	// The type of the value eg Integer
+	Type Asn1BER
+
+	// The value to be set by the SNMP set, or the value when decoding a
+	// response.
+	Value interface{}
+
+	// nameBytes holds the raw BER content octets of Name, captured during
+	// VarBind decoding when GoSNMP.LargeOIDArcs is set. It is nil
+	// otherwise, in which case OIDBig returns an error.
+	nameBytes []byte
+}
+
+// SnmpDecodePacket exposes SNMP packet parsing to external callers. This is
+// useful for processing traffic captured from other sources, and for
+// fuzzing/testing harnesses built around the BER decoder.
+func (x *GoSNMP) SnmpDecodePacket(packet []byte) (*SnmpPacket, error) {
+	response := new(SnmpPacket)
+	if err := x.unmarshal(packet, response); err != nil {
+		return response, fmt.Errorf("unable to decode packet: %w", err)
+	}
+	return response, nil
+}
+
+// readTLV splits data into a BER tag, its content octets, and the
+// remaining bytes after this element, using parseLength for the
+// header/length accounting. It never panics, even on truncated or
+// malformed input.
+func readTLV(data []byte) (tag byte, content, rest []byte, err error) {
+	if len(data) == 0 {
+		return 0, nil, nil, ErrInvalidPacketLength
+	}
+	length, cursor, err := parseLength(data)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if length > len(data) || cursor > length {
+		return 0, nil, nil, ErrInvalidPacketLength
+	}
+	return data[0], data[cursor:length], data[length:], nil
+}
+
+// unmarshal decodes the SNMPv1/SNMPv2c message envelope (SEQUENCE {
+// version INTEGER, community OCTET STRING, pdu ANY }) and the PDU's
+// request-id/error-status/error-index/varbind-list into response.
+func (x *GoSNMP) unmarshal(packet []byte, response *SnmpPacket) error {
+	tag, envelope, _, err := readTLV(packet)
+	if err != nil {
+		return err
+	}
+	if tag != byte(Sequence) {
+		return fmt.Errorf("invalid packet: expected a SEQUENCE, got tag %#x", tag)
+	}
+
+	tag, content, rest, err := readTLV(envelope)
+	if err != nil {
+		return err
+	}
+	if tag != byte(Integer) {
+		return fmt.Errorf("invalid packet: expected a version INTEGER, got tag %#x", tag)
+	}
+	version, err := parseUint64(content)
+	if err != nil {
+		return err
+	}
+	response.Version = SnmpVersion(version)
+
+	tag, content, rest, err = readTLV(rest)
+	if err != nil {
+		return err
+	}
+	if tag != byte(OctetString) {
+		return fmt.Errorf("invalid packet: expected a community OCTET STRING, got tag %#x", tag)
+	}
+	response.Community = string(content)
+
+	tag, content, _, err = readTLV(rest)
+	if err != nil {
+		return err
+	}
+	if tag&0xc0 != 0x80 {
+		return fmt.Errorf("invalid packet: expected a context-specific PDU tag, got %#x", tag)
+	}
+	response.PDUType = PDUType(tag)
+
+	tag, content, rest, err = readTLV(content)
+	if err != nil {
+		return err
+	}
+	if tag != byte(Integer) {
+		return fmt.Errorf("invalid packet: expected a request-id INTEGER, got tag %#x", tag)
+	}
+	requestID, err := parseUint64(content)
+	if err != nil {
+		return err
+	}
+	response.RequestID = uint32(requestID)
+
+	tag, content, rest, err = readTLV(rest)
+	if err != nil {
+		return err
+	}
+	if tag != byte(Integer) {
+		return fmt.Errorf("invalid packet: expected an error-status INTEGER, got tag %#x", tag)
+	}
+	errStatus, err := parseUint64(content)
+	if err != nil {
+		return err
+	}
+	response.Error = uint32(errStatus)
+
+	tag, content, rest, err = readTLV(rest)
+	if err != nil {
+		return err
+	}
+	if tag != byte(Integer) {
+		return fmt.Errorf("invalid packet: expected an error-index INTEGER, got tag %#x", tag)
+	}
+	errIndex, err := parseUint64(content)
+	if err != nil {
+		return err
+	}
+	response.ErrorIndex = uint32(errIndex)
+
+	tag, varBindList, _, err := readTLV(rest)
+	if err != nil {
+		return err
+	}
+	if tag != byte(Sequence) {
+		return fmt.Errorf("invalid packet: expected a varbind-list SEQUENCE, got tag %#x", tag)
+	}
+
+	for len(varBindList) > 0 {
+		var vbTag byte
+		var vbContent []byte
+		vbTag, vbContent, varBindList, err = readTLV(varBindList)
+		if err != nil {
+			return err
+		}
+		if vbTag != byte(Sequence) {
+			return fmt.Errorf("invalid varbind: expected a SEQUENCE, got tag %#x", vbTag)
+		}
+		pdu, err := x.unmarshalVarBind(vbContent)
+		if err != nil {
+			return err
+		}
+		response.Variables = append(response.Variables, pdu)
+	}
+	return nil
+}
+
+// unmarshalVarBind decodes a single VarBind SEQUENCE { name OBJECT
+// IDENTIFIER, value ANY }. When x.LargeOIDArcs is set, it also retains the
+// name's raw content octets so SnmpPDU.OIDBig can later decode them; in that
+// case an arc that overflows uint32 no longer fails the whole VarBind (and
+// packet) the way it otherwise would — Name is left empty and callers are
+// expected to fall back to OIDBig.
+func (x *GoSNMP) unmarshalVarBind(data []byte) (SnmpPDU, error) {
+	tag, oidContent, rest, err := readTLV(data)
+	if err != nil {
+		return SnmpPDU{}, err
+	}
+	if tag != byte(ObjectIdentifier) {
+		return SnmpPDU{}, fmt.Errorf("invalid varbind: expected an OBJECT IDENTIFIER, got tag %#x", tag)
+	}
+
+	var pdu SnmpPDU
+	if x.LargeOIDArcs {
+		pdu.nameBytes = append([]byte(nil), oidContent...)
+	}
+	name, err := parseObjectIdentifier(oidContent)
+	switch {
+	case err == nil:
+		pdu.Name = name
+	case x.LargeOIDArcs && err == ErrBase128IntegerTooLarge:
+		// Name stays empty; OIDBig is the only way to read this arc.
+	default:
+		return SnmpPDU{}, err
+	}
+
+	valTag, valContent, _, err := readTLV(rest)
+	if err != nil {
+		return SnmpPDU{}, err
+	}
+	pdu.Type = Asn1BER(valTag)
+	pdu.Value = append([]byte(nil), valContent...)
+	return pdu, nil
+}
+
+// OIDBig returns the PDU's object identifier decoded as arbitrary-precision
+// sub-identifiers via parseObjectIdentifierBig. It is only populated when
+// the GoSNMP that produced this PDU had LargeOIDArcs set; callers that need
+// the common case can keep using the string-form Name.
+func (pdu SnmpPDU) OIDBig() (OIDBig, error) {
+	if pdu.nameBytes == nil {
+		return nil, errOIDBigNotCaptured
+	}
+	return parseObjectIdentifierBig(pdu.nameBytes)
+}
@@ -0,0 +1,250 @@
+// Copyright 2012 The GoSNMP Authors. All rights reserved.  Use of this
+// source code is governed by a BSD-style license that can be found in the
+// LICENSE file.
+
+// Copyright 2009 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gosnmp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/gosnmp/gosnmp/ber"
+)
+
+// Max oid sub-identifier value
+// https://tools.ietf.org/html/rfc2578#section-7.1.3
+const MaxObjectSubIdentifierValue = 4294967295
+
+// helper error modes
+var (
+	ErrBase128IntegerTooLarge  = errors.New("base 128 integer too large")
+	ErrBase128IntegerTruncated = errors.New("base 128 integer truncated")
+	ErrIntegerTooLarge         = errors.New("integer too large")
+	ErrInvalidOidLength        = errors.New("invalid OID length")
+	ErrInvalidPacketLength     = errors.New("invalid packet length")
+
+	errOIDBigNotCaptured = errors.New("gosnmp: OIDBig is only available when GoSNMP.LargeOIDArcs is enabled")
+)
+
+/*
+	snmp Integer32 and INTEGER:
+	-2^31 and 2^31-1 inclusive (-2147483648 to 2147483647 decimal)
+	(FYI https://groups.google.com/forum/#!topic/comp.protocols.snmp/1xaAMzCe_hE)
+
+	versus:
+
+	snmp Counter32, Gauge32, TimeTicks, Unsigned32: (below)
+	non-negative integer, maximum value of 2^32-1 (4294967295 decimal)
+*/
+
+// marshalInt32 builds a byte representation of a signed 32 bit int in
+// BigEndian form, ie -2^31 and 2^31-1 inclusive (-2147483648 to 2147483647
+// decimal). It is a thin wrapper around ber.Encoder.EncodeInteger32.
+func marshalInt32(value int) ([]byte, error) {
+	if value < math.MinInt32 || value > math.MaxInt32 {
+		return nil, fmt.Errorf("unable to marshal: %d overflows int32", value)
+	}
+	out := new(bytes.Buffer)
+	ber.NewEncoder(out).EncodeInteger32(int32(value))
+	return out.Bytes(), nil
+}
+
+// marshalUint64 builds a byte representation of an unsigned 64 bit int (eg
+// Counter64) in BigEndian form, prepending a zero byte if the top bit would
+// otherwise flip the encoding negative. It is a thin wrapper around
+// ber.Encoder.EncodeCounter64.
+func marshalUint64(v interface{}) ([]byte, error) {
+	source, ok := v.(uint64)
+	if !ok {
+		return nil, fmt.Errorf("unable to marshal %T to uint64", v)
+	}
+	out := new(bytes.Buffer)
+	ber.NewEncoder(out).EncodeCounter64(source)
+	return out.Bytes(), nil
+}
+
+// marshalUint32 builds a byte representation of an unsigned int in BigEndian
+// form, for Counter32, Gauge32, TimeTicks, Unsigned32, SNMPError. It is a
+// thin wrapper around ber.Encoder.EncodeCounter64: the minimal-octet,
+// prepend-a-zero-if-the-top-bit-is-set encoding is the same for any
+// unsigned width, so there's no need for a uint32-specific BER method.
+func marshalUint32(v interface{}) ([]byte, error) {
+	var source uint32
+	switch val := v.(type) {
+	case uint32:
+		source = val
+	case uint:
+		source = uint32(val)
+	case uint8:
+		source = uint32(val)
+	// We could do others here, but coercing from anything else is dangerous.
+	// Even uint could be 64 bits, though in practice nothing we work with is.
+	default:
+		return nil, fmt.Errorf("unable to marshal %T to uint32", v)
+	}
+	out := new(bytes.Buffer)
+	ber.NewEncoder(out).EncodeCounter64(uint64(source))
+	return out.Bytes(), nil
+}
+
+// splitObjectIdentifier parses a dotted string OID, eg ".1.3.6.1.2.1.1.1.0",
+// into its sub-identifier arcs, applying the same validation
+// marshalObjectIdentifier has always done: the first arc is at most 6, the
+// second is less than 40, and every other arc fits MaxObjectSubIdentifierValue.
+func splitObjectIdentifier(oid string) ([]uint32, error) {
+	var arcs []uint32
+	oidLength := len(oid)
+	i := 0
+	for j := 0; j < oidLength; {
+		if oid[j] == '.' {
+			j++
+			continue
+		}
+		var val uint64
+		for j < oidLength && oid[j] != '.' {
+			ch := uint64(oid[j] - '0')
+			if ch > 9 {
+				return nil, fmt.Errorf("unable to marshal OID: Invalid object identifier")
+			}
+			val = val*10 + ch
+			j++
+		}
+		switch i {
+		case 0:
+			if val > 6 {
+				return nil, fmt.Errorf("unable to marshal OID: Invalid object identifier")
+			}
+		case 1:
+			if val >= 40 {
+				return nil, fmt.Errorf("unable to marshal OID: Invalid object identifier")
+			}
+		default:
+			if val > MaxObjectSubIdentifierValue {
+				return nil, fmt.Errorf("unable to marshal OID: Value out of range")
+			}
+		}
+		arcs = append(arcs, uint32(val))
+		i++
+	}
+	if i < 2 || i > 128 {
+		return nil, fmt.Errorf("unable to marshal OID: Invalid object identifier")
+	}
+	return arcs, nil
+}
+
+// marshalObjectIdentifier builds a byte representation of the dotted string
+// OID, eg ".1.3.6.1.2.1.1.1.0". It is a thin wrapper around ber.Encoder,
+// kept for existing callers; new code in hot paths (bulk-walk in
+// particular) should use a shared ber.Encoder directly, per
+// BenchmarkMarshalObjectIdentifier.
+func marshalObjectIdentifier(oid string) ([]byte, error) {
+	arcs, err := splitObjectIdentifier(oid)
+	if err != nil {
+		return []byte{}, err
+	}
+	out := new(bytes.Buffer)
+	if err := ber.NewEncoder(out).EncodeOID(arcs); err != nil {
+		return []byte{}, fmt.Errorf("unable to marshal OID: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// parseLength parses and calculates a BER length prefix, returning the
+// total element length (header + content) and the header length, and
+// returns an error when invalid data is detected. It is a thin wrapper
+// around ber.Decoder.DecodeLength.
+func parseLength(data []byte) (int, int, error) {
+	length, cursor, err := ber.NewDecoder(data).DecodeLength()
+	if err != nil {
+		return 0, 0, ErrInvalidPacketLength
+	}
+	return length, cursor, nil
+}
+
+// parseObjectIdentifier parses an OBJECT IDENTIFIER from the given bytes and
+// returns it. An object identifier is a sequence of variable length integers
+// that are assigned in a hierarchy. It is a thin wrapper around
+// ber.Decoder, which implicitly bounds each arc to uint32 range; see
+// parseObjectIdentifierBig for arcs that legitimately exceed it.
+func parseObjectIdentifier(src []byte) (string, error) {
+	arcs, err := ber.NewDecoder(src).DecodeOID(make([]uint32, 0, len(src)+1))
+	if err != nil {
+		switch err {
+		case ber.ErrTruncatedOID:
+			return "", ErrInvalidOidLength
+		case ber.ErrOIDArcOverflow:
+			return "", ErrBase128IntegerTooLarge
+		default:
+			return "", err
+		}
+	}
+
+	out := new(bytes.Buffer)
+	for _, arc := range arcs {
+		out.WriteByte('.')
+		out.WriteString(strconv.FormatUint(uint64(arc), 10))
+	}
+	return out.String(), nil
+}
+
+// OIDBig is the arbitrary-precision equivalent of the dotted string OID
+// returned by parseObjectIdentifier. Each element is one arc of the
+// identifier; unlike the string form, no arc is bounded to uint32.
+type OIDBig []*big.Int
+
+// parseObjectIdentifierBig parses an OBJECT IDENTIFIER the same way
+// parseObjectIdentifier does, except each base-128 continuation run is
+// accumulated into a big.Int instead of a uint32-bounded value, so
+// arcs that legitimately exceed uint32 round-trip without truncating.
+func parseObjectIdentifierBig(src []byte) (OIDBig, error) {
+	if len(src) == 0 {
+		return nil, ErrInvalidOidLength
+	}
+
+	out := make(OIDBig, 0, len(src)+1)
+	out = append(out, big.NewInt(int64(src[0])/40))
+	out = append(out, big.NewInt(int64(src[0])%40))
+
+	for offset := 1; offset < len(src); {
+		v, next, err := parseBase128BigInt(src, offset)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+		offset = next
+	}
+	return out, nil
+}
+
+// parseBase128BigInt accumulates a base-128 continuation run into a
+// big.Int, placing no limit on the number of continuation bytes.
+func parseBase128BigInt(data []byte, initOffset int) (*big.Int, int, error) {
+	ret := new(big.Int)
+	for offset := initOffset; offset < len(data); offset++ {
+		b := data[offset]
+		ret.Lsh(ret, 7)
+		ret.Or(ret, big.NewInt(int64(b&0x7f)))
+		if b&0x80 == 0 {
+			return ret, offset + 1, nil
+		}
+	}
+	return nil, 0, ErrBase128IntegerTruncated
+}
+
+// parseUint64 treats the given bytes as a big-endian, unsigned integer and
+// returns the result. It is a thin wrapper around ber.Decoder.DecodeUint64.
+func parseUint64(data []byte) (uint64, error) {
+	ret, err := ber.NewDecoder(data).DecodeUint64()
+	if err != nil {
+		return 0, ErrIntegerTooLarge
+	}
+	return ret, nil
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/base64"
 	"testing"
 	"reflect"
+	"math/big"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -44,6 +45,117 @@ func TestParseObjectIdentifierWithOtherOid(t *testing.T) {
 	}
 }
 
+func bigInts(values ...int64) OIDBig {
+	out := make(OIDBig, len(values))
+	for i, v := range values {
+		out[i] = big.NewInt(v)
+	}
+	return out
+}
+
+func TestParseObjectIdentifierBig(t *testing.T) {
+	// Same arcs as TestParseObjectIdentifier: every arc fits uint32, so
+	// this should agree with the string-form decoder.
+	oid := []byte{43, 6, 1, 2, 1, 31, 1, 1, 1, 10, 143, 255, 255, 255, 127}
+	expected := bigInts(1, 3, 6, 1, 2, 1, 31, 1, 1, 1, 10, 4294967295)
+
+	result, err := parseObjectIdentifierBig(oid)
+	if err != nil {
+		t.Fatalf("parseObjectIdentifierBig(%v) returned unexpected error: %v", oid, err)
+	}
+	if len(result) != len(expected) {
+		t.Fatalf("parseObjectIdentifierBig(%v) = %v, want %v", oid, result, expected)
+	}
+	for i := range expected {
+		if result[i].Cmp(expected[i]) != 0 {
+			t.Errorf("parseObjectIdentifierBig(%v)[%d] = %s, want %s", oid, i, result[i], expected[i])
+		}
+	}
+}
+
+func TestParseObjectIdentifierBigOverflowsUint32(t *testing.T) {
+	// The last arc is encoded across 6 continuation bytes (4398046511103),
+	// which overflows uint32 and would be rejected by parseObjectIdentifier
+	// via ErrBase128IntegerTooLarge; parseObjectIdentifierBig has no such
+	// bound and must decode it without error.
+	oid := []byte{43, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+	expected := bigInts(1, 3, 4398046511103)
+
+	if _, err := parseObjectIdentifier(oid); err == nil {
+		t.Fatalf("parseObjectIdentifier(%v) unexpectedly succeeded on an arc that overflows uint32", oid)
+	}
+
+	result, err := parseObjectIdentifierBig(oid)
+	if err != nil {
+		t.Fatalf("parseObjectIdentifierBig(%v) returned unexpected error: %v", oid, err)
+	}
+	if len(result) != len(expected) || result[len(result)-1].Cmp(expected[len(expected)-1]) != 0 {
+		t.Errorf("parseObjectIdentifierBig(%v) = %v, want %v", oid, result, expected)
+	}
+}
+
+func TestParseObjectIdentifierBigTruncated(t *testing.T) {
+	// 0xff has its continuation bit set but is the last byte: the arc is
+	// truncated mid-encoding.
+	oid := []byte{43, 6, 0xff}
+
+	if _, err := parseObjectIdentifierBig(oid); err != ErrBase128IntegerTruncated {
+		t.Errorf("parseObjectIdentifierBig(%v) error = %v, want %v", oid, err, ErrBase128IntegerTruncated)
+	}
+}
+
+// TestSnmpDecodePacketLargeOIDArcs decodes a hand-built GetResponse
+// packet whose sole VarBind's OID has an arc that overflows uint32 (the
+// same arc as TestParseObjectIdentifierBigOverflowsUint32). With
+// LargeOIDArcs set, SnmpDecodePacket must still succeed, leaving Name empty
+// and letting OIDBig recover the arc; with LargeOIDArcs unset, decoding
+// must still fail as it always has.
+func TestSnmpDecodePacketLargeOIDArcs(t *testing.T) {
+	packet := []byte{
+		0x30, 0x26, 0x02, 0x01, 0x01, 0x04, 0x06, 0x70, 0x75, 0x62, 0x6c, 0x69,
+		0x63, 0xa2, 0x19, 0x02, 0x01, 0x01, 0x02, 0x01, 0x00, 0x02, 0x01, 0x00,
+		0x30, 0x0e, 0x30, 0x0c, 0x06, 0x07, 0x2b, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0x7f, 0x02, 0x01, 0x00,
+	}
+
+	g := &GoSNMP{
+		Target:       "127.0.0.1",
+		Port:         161,
+		Community:    "public",
+		Version:      Version2c,
+		LargeOIDArcs: true,
+	}
+	result, err := g.SnmpDecodePacket(packet)
+	if err != nil {
+		t.Fatalf("SnmpDecodePacket(%x) with LargeOIDArcs returned unexpected error: %v", packet, err)
+	}
+	if len(result.Variables) != 1 {
+		t.Fatalf("SnmpDecodePacket(%x) returned %d variables, want 1", packet, len(result.Variables))
+	}
+	pdu := result.Variables[0]
+	if pdu.Name != "" {
+		t.Errorf("pdu.Name = %q, want empty (arc overflows uint32)", pdu.Name)
+	}
+	oid, err := pdu.OIDBig()
+	if err != nil {
+		t.Fatalf("pdu.OIDBig() returned unexpected error: %v", err)
+	}
+	want := bigInts(1, 3, 4398046511103)
+	if len(oid) != len(want) || oid[len(oid)-1].Cmp(want[len(want)-1]) != 0 {
+		t.Errorf("pdu.OIDBig() = %v, want %v", oid, want)
+	}
+
+	gNoBig := &GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      161,
+		Community: "public",
+		Version:   Version2c,
+	}
+	if _, err := gNoBig.SnmpDecodePacket(packet); err == nil {
+		t.Errorf("SnmpDecodePacket(%x) without LargeOIDArcs unexpectedly succeeded", packet)
+	}
+}
+
 func BenchmarkParseObjectIdentifier(b *testing.B) {
 	oid := []byte{43, 6, 3, 30, 11, 1, 10}
 	for i := 0; i < b.N; i++ {